@@ -1,42 +1,80 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
+	"net/url"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 )
 
 const usageText = `Usage: gh gist-new [name] [flags]
+       gh gist-new update [name] [flags]
 
 Create a new gist from all regular, non-dotfiles inside [name]. When [name] is '.',
 the current directory is used. The directory must not contain subdirectories or
 directory symlinks.
 
+The "update" form syncs [name] (a directory previously created by this tool) back
+to the gist it was cloned from: files added or changed locally are uploaded, files
+removed locally are deleted from the gist.
+
 Flags:
   --public          Create the gist as public (defaults to secret)
   -d, --description Description to attach to the gist (must not be empty)
+  --push            Commit the gathered files and push them to the gist after creation
+  --recursive       Include files in subdirectories, flattening their paths into filenames
+  --path-separator  Separator used to flatten nested paths (default "__")
+  --concurrency     Number of files "update" reads in parallel (default: number of CPUs);
+                    creating a gist reads its files one at a time
+  --git-name        Git user.name to set on the cloned directory (default: autodetected)
+  --git-email       Git user.email to set on the cloned directory (default: autodetected)
+  --hostname        GitHub Enterprise Server hostname to target (default: $GH_HOST, or github.com)
   --verbose         Show detailed per-file logs and timing information
   -h, --help        Show this message
 `
 
+const defaultPathSeparator = "__"
+
+const (
+	// maxGistFileBytes is GitHub's approximate per-file size limit for gists.
+	maxGistFileBytes = 1 << 20 // ~1 MiB
+	// maxGistTotalBytes is GitHub's approximate total size limit for a gist.
+	maxGistTotalBytes = 10 << 20 // ~10 MiB
+)
+
 var errHelpRequested = errors.New("help requested")
 
 type options struct {
-	name        string
-	public      bool
-	description string
-	verbose     bool
+	name          string
+	public        bool
+	description   string
+	push          bool
+	recursive     bool
+	pathSeparator string
+	concurrency   int
+	gitName       string
+	gitEmail      string
+	hostname      string
+	verbose       bool
 }
 
 type stringFlag struct {
@@ -55,17 +93,12 @@ func (s *stringFlag) Set(v string) error {
 }
 
 type filePayload struct {
-	Name    string
+	Name    string // gist filename, flattened from RelPath when --recursive is set
 	Path    string
+	RelPath string // path relative to the target directory
 	Content []byte
 }
 
-type gistCreateRequest struct {
-	Description string              `json:"description,omitempty"`
-	Public      bool                `json:"public"`
-	Files       map[string]gistFile `json:"files"`
-}
-
 type gistFile struct {
 	Content string `json:"content"`
 }
@@ -75,6 +108,41 @@ type gistCreateResponse struct {
 	HTMLURL string `json:"html_url"`
 }
 
+// gistClient is the subset of api.RESTClient this tool actually calls. It
+// exists as a seam so tests can inject a fake client instead of hitting the
+// network; api.RESTClient satisfies it without any changes on its end.
+type gistClient interface {
+	Post(path string, body io.Reader, response interface{}) error
+	Get(path string, response interface{}) error
+	Patch(path string, body io.Reader, response interface{}) error
+}
+
+// newGistClient builds a gistClient targeting hostname, or the gh CLI's
+// default host (github.com, honoring a GH_HOST override already folded into
+// hostname by the flag default) when hostname is empty.
+func newGistClient(hostname string) (gistClient, error) {
+	if hostname == "" {
+		return api.DefaultRESTClient()
+	}
+	return api.NewRESTClient(api.ClientOptions{Host: hostname})
+}
+
+type gistPatchFile struct {
+	Filename *string `json:"filename,omitempty"`
+	Content  string  `json:"content,omitempty"`
+}
+
+type gistUpdateRequest struct {
+	Description string                    `json:"description,omitempty"`
+	Files       map[string]*gistPatchFile `json:"files"`
+}
+
+type gistGetResponse struct {
+	ID          string              `json:"id"`
+	Description string              `json:"description"`
+	Files       map[string]gistFile `json:"files"`
+}
+
 type logger struct {
 	verbose bool
 }
@@ -100,6 +168,13 @@ func main() {
 }
 
 func run(args []string) error {
+	if len(args) > 0 && args[0] == "update" {
+		return runUpdate(args[1:])
+	}
+	return runCreate(args)
+}
+
+func runCreate(args []string) error {
 	opts, err := parseArgs(args)
 	if err != nil {
 		return err
@@ -115,16 +190,16 @@ func run(args []string) error {
 
 	log.Info("Collecting files for gist…")
 	startScan := time.Now()
-	files, err := gatherFiles(targetDir, displayName, log)
+	candidates, err := gatherFiles(targetDir, displayName, opts, log)
 	if err != nil {
 		return err
 	}
-	log.Info("Collected %d file(s)", len(files))
+	log.Info("Collected %d file(s)", len(candidates))
 	log.Verbose("File collection completed in %s", time.Since(startScan).Round(time.Millisecond))
 
 	log.Info("Creating gist via GitHub API…")
 	startCreate := time.Now()
-	gistURL, gistID, err := createGist(files, opts, log)
+	gistURL, gistID, err := createGist(candidates, opts, log)
 	if err != nil {
 		return err
 	}
@@ -132,11 +207,25 @@ func run(args []string) error {
 
 	log.Info("Cloning gist metadata into target directory…")
 	startClone := time.Now()
-	if err := cloneGistMetadata(gistID, targetDir, log); err != nil {
+	repo, err := cloneGistMetadata(gistID, opts.hostname, targetDir, log)
+	if err != nil {
 		return err
 	}
 	log.Verbose("Metadata cloning completed in %s", time.Since(startClone).Round(time.Millisecond))
 
+	if err := ensureGitIdentity(targetDir, opts, log); err != nil {
+		return err
+	}
+
+	if opts.push {
+		log.Info("Committing and pushing gathered files…")
+		startPush := time.Now()
+		if err := pushInitialCommit(repo, targetDir, candidates, opts, log); err != nil {
+			return err
+		}
+		log.Verbose("Push completed in %s", time.Since(startPush).Round(time.Millisecond))
+	}
+
 	log.Info("Done! Gist ready at %s", gistURL)
 	return nil
 }
@@ -146,6 +235,13 @@ func parseArgs(args []string) (options, error) {
 	fs := flag.NewFlagSet("gh gist-new", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 	fs.BoolVar(&opts.public, "public", false, "create a public gist")
+	fs.BoolVar(&opts.push, "push", false, "commit and push the gathered files to the gist after creation")
+	fs.BoolVar(&opts.recursive, "recursive", false, "include files in subdirectories, flattening their paths into filenames")
+	fs.StringVar(&opts.pathSeparator, "path-separator", defaultPathSeparator, "separator used to flatten nested paths")
+	fs.IntVar(&opts.concurrency, "concurrency", runtime.NumCPU(), "number of files to read in parallel")
+	fs.StringVar(&opts.gitName, "git-name", "", "git user.name to set on the cloned directory (default: autodetected)")
+	fs.StringVar(&opts.gitEmail, "git-email", "", "git user.email to set on the cloned directory (default: autodetected)")
+	fs.StringVar(&opts.hostname, "hostname", os.Getenv("GH_HOST"), "GitHub Enterprise Server hostname to target")
 	fs.BoolVar(&opts.verbose, "verbose", false, "enable verbose logging")
 	var desc stringFlag
 	fs.Var(&desc, "description", "description for the gist")
@@ -285,7 +381,17 @@ func ensureNotGitRepo(dir string) error {
 	return nil
 }
 
-func gatherFiles(dir, displayName string, log logger) ([]filePayload, error) {
+// gatherFiles discovers the files a gist will contain without reading their
+// contents: callers read candidates' bytes themselves, at the point (and in
+// the quantity) their flow actually needs them.
+func gatherFiles(dir, displayName string, opts options, log logger) ([]fileCandidate, error) {
+	if opts.recursive {
+		return gatherFilesRecursive(dir, displayName, opts, log)
+	}
+	return gatherFilesFlat(dir, displayName, opts, log)
+}
+
+func gatherFilesFlat(dir, displayName string, opts options, log logger) ([]fileCandidate, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("read directory %s: %w", dir, err)
@@ -293,43 +399,216 @@ func gatherFiles(dir, displayName string, log logger) ([]filePayload, error) {
 	sort.Slice(entries, func(i, j int) bool {
 		return entries[i].Name() < entries[j].Name()
 	})
-	var files []filePayload
+	var candidates []fileCandidate
 	for _, entry := range entries {
 		info, err := entry.Info()
 		if err != nil {
 			return nil, fmt.Errorf("inspect %s: %w", entry.Name(), err)
 		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			log.Verbose("Skipping dotfile %s", entry.Name())
+			continue
+		}
 		if entry.Type()&os.ModeSymlink != 0 && info.IsDir() {
 			return nil, fmt.Errorf("symlink %s targets a directory; gists cannot include directories", entry.Name())
 		}
 		if info.IsDir() {
-			return nil, fmt.Errorf("subdirectory %s detected; gists only support flat file sets", entry.Name())
-		}
-		if strings.HasPrefix(entry.Name(), ".") {
-			log.Verbose("Skipping dotfile %s", entry.Name())
-			continue
+			return nil, fmt.Errorf("subdirectory %s detected; pass --recursive to include nested files", entry.Name())
 		}
 		if !info.Mode().IsRegular() {
 			log.Verbose("Skipping non-regular file %s", entry.Name())
 			continue
 		}
-		path := filepath.Join(dir, entry.Name())
-		content, err := os.ReadFile(path)
+		candidates = append(candidates, fileCandidate{name: entry.Name(), relPath: entry.Name(), path: filepath.Join(dir, entry.Name())})
+	}
+	if len(candidates) == 0 {
+		f, err := bootstrapDefaultFile(dir, displayName, log)
 		if err != nil {
-			return nil, fmt.Errorf("read %s: %w", entry.Name(), err)
+			return nil, err
 		}
-		files = append(files, filePayload{Name: entry.Name(), Path: path, Content: content})
-		log.Verbose("Queued %s (%d bytes)", entry.Name(), len(content))
+		return []fileCandidate{f}, nil
 	}
-	if len(files) == 0 {
-		name := defaultFileName(displayName)
-		content := []byte(fmt.Sprintf("# %s\n", displayName))
-		path := filepath.Join(dir, name)
-		if err := os.WriteFile(path, content, 0o644); err != nil {
-			return nil, fmt.Errorf("bootstrap default file %s: %w", name, err)
+	return candidates, nil
+}
+
+// gatherFilesRecursive walks dir, flattening each file's relative path into a
+// gist-legal filename by replacing the OS path separator with opts.pathSeparator
+// (e.g. "src/api/handler.go" becomes "src__api__handler.go").
+func gatherFilesRecursive(dir, displayName string, opts options, log logger) ([]fileCandidate, error) {
+	separator := opts.pathSeparator
+	if separator == "" {
+		separator = defaultPathSeparator
+	}
+
+	type candidate struct {
+		rel     string
+		path    string
+		encoded string
+	}
+	var candidates []candidate
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, relErr := filepath.Rel(dir, p)
+		if relErr != nil {
+			return relErr
+		}
+		if rel == "." {
+			return nil
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") {
+				log.Verbose("Skipping dotfile directory %s", rel)
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			log.Verbose("Skipping dotfile %s", rel)
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("inspect %s: %w", rel, err)
+		}
+		if !info.Mode().IsRegular() {
+			log.Verbose("Skipping non-regular file %s", rel)
+			return nil
+		}
+		encoded := strings.ReplaceAll(rel, string(os.PathSeparator), separator)
+		candidates = append(candidates, candidate{rel: rel, path: p, encoded: encoded})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk directory %s: %w", dir, err)
+	}
+
+	byEncoded := make(map[string][]string, len(candidates))
+	for _, c := range candidates {
+		byEncoded[c.encoded] = append(byEncoded[c.encoded], c.rel)
+	}
+	for encoded, sources := range byEncoded {
+		if len(sources) > 1 {
+			sort.Strings(sources)
+			return nil, fmt.Errorf("paths %s all encode to gist filename %q; rerun with a different --path-separator", strings.Join(sources, ", "), encoded)
+		}
+	}
+
+	if len(candidates) == 0 {
+		f, err := bootstrapDefaultFile(dir, displayName, log)
+		if err != nil {
+			return nil, err
 		}
-		files = append(files, filePayload{Name: name, Path: path, Content: content})
-		log.Info("Directory was empty; created %s", name)
+		return []fileCandidate{f}, nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].encoded < candidates[j].encoded })
+	fileCandidates := make([]fileCandidate, len(candidates))
+	for i, c := range candidates {
+		fileCandidates[i] = fileCandidate{name: c.encoded, relPath: c.rel, path: c.path}
+	}
+	return fileCandidates, nil
+}
+
+func bootstrapDefaultFile(dir, displayName string, log logger) (fileCandidate, error) {
+	name := defaultFileName(displayName)
+	content := []byte(fmt.Sprintf("# %s\n", displayName))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fileCandidate{}, fmt.Errorf("bootstrap default file %s: %w", name, err)
+	}
+	log.Info("Directory was empty; created %s", name)
+	return fileCandidate{name: name, relPath: name, path: path}, nil
+}
+
+// fileCandidate is a file discovered by gatherFilesFlat/gatherFilesRecursive
+// that still needs its content read from disk.
+type fileCandidate struct {
+	name    string // gist filename
+	relPath string // path relative to the target directory, for logging
+	path    string // absolute path to read
+}
+
+// readCandidates reads candidates' contents using a bounded worker pool
+// (sized by opts.concurrency, default runtime.NumCPU()), enforcing GitHub's
+// per-file and total gist size limits up front rather than letting the
+// update API call reject an oversized payload later.
+func readCandidates(candidates []fileCandidate, opts options, log logger) ([]filePayload, error) {
+	concurrency := opts.concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	if concurrency > len(candidates) {
+		concurrency = len(candidates)
+	}
+
+	type result struct {
+		index int
+		file  filePayload
+		err   error
+	}
+
+	jobs := make(chan int)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		worker := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c := candidates[i]
+				start := time.Now()
+				info, err := os.Stat(c.path)
+				if err != nil {
+					results <- result{index: i, err: fmt.Errorf("inspect %s: %w", c.relPath, err)}
+					continue
+				}
+				if info.Size() > maxGistFileBytes {
+					results <- result{index: i, err: fmt.Errorf("%s is %d bytes, exceeding GitHub's ~1 MiB per-file gist limit", c.relPath, info.Size())}
+					continue
+				}
+				content, err := os.ReadFile(c.path)
+				if err != nil {
+					results <- result{index: i, err: fmt.Errorf("read %s: %w", c.relPath, err)}
+					continue
+				}
+				log.Verbose("worker %d read %s as %s (%d bytes) in %s", worker, c.relPath, c.name, len(content), time.Since(start).Round(time.Millisecond))
+				results <- result{index: i, file: filePayload{Name: c.name, Path: c.path, RelPath: c.relPath, Content: content}}
+			}
+		}()
+	}
+
+	go func() {
+		for i := range candidates {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	files := make([]filePayload, len(candidates))
+	var firstErr error
+	var total int64
+	for r := range results {
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		files[r.index] = r.file
+		total += int64(len(r.file.Content))
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if total > maxGistTotalBytes {
+		return nil, fmt.Errorf("gathered files total %d bytes, exceeding GitHub's ~10 MiB gist size limit", total)
 	}
 	return files, nil
 }
@@ -338,27 +617,25 @@ func defaultFileName(name string) string {
 	return fmt.Sprintf("%s.md", name)
 }
 
-func createGist(files []filePayload, opts options, log logger) (string, string, error) {
-	req := gistCreateRequest{
-		Public: opts.public,
-		Files:  make(map[string]gistFile, len(files)),
-	}
-	if opts.description != "" {
-		req.Description = opts.description
-	}
-	for _, f := range files {
-		req.Files[f.Name] = gistFile{Content: string(f.Content)}
+func createGist(candidates []fileCandidate, opts options, log logger) (string, string, error) {
+	if err := validateCandidateSizes(candidates); err != nil {
+		return "", "", err
 	}
-	client, err := api.DefaultRESTClient()
+	client, err := newGistClient(opts.hostname)
 	if err != nil {
 		return "", "", fmt.Errorf("init GitHub client: %w", err)
 	}
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return "", "", fmt.Errorf("encode gist payload: %w", err)
-	}
+
+	// Stream the request body straight from disk instead of reading every
+	// candidate into memory up front: encodeGistCreateRequest reads one
+	// file at a time, so peak memory stays proportional to the largest
+	// single file rather than the whole gist.
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(encodeGistCreateRequest(pw, candidates, opts))
+	}()
 	var resp gistCreateResponse
-	if err := client.Post("gists", bytes.NewReader(payload), &resp); err != nil {
+	if err := client.Post("gists", pr, &resp); err != nil {
 		return "", "", fmt.Errorf("create gist via GitHub API: %w", err)
 	}
 	if resp.ID == "" || resp.HTMLURL == "" {
@@ -372,147 +649,547 @@ func createGist(files []filePayload, opts options, log logger) (string, string,
 	return resp.HTMLURL, resp.ID, nil
 }
 
-func cloneGistMetadata(gistID, targetDir string, log logger) error {
-	tempParent, err := os.MkdirTemp("", "gh-gist-new-")
-	if err != nil {
-		return fmt.Errorf("create temporary directory for cloning: %w", err)
+// validateCandidateSizes stats (but does not read) every candidate so an
+// oversized file or gist is rejected before the create request is even
+// opened, matching the up-front validation readCandidates does for update.
+func validateCandidateSizes(candidates []fileCandidate) error {
+	var total int64
+	for _, c := range candidates {
+		info, err := os.Stat(c.path)
+		if err != nil {
+			return fmt.Errorf("inspect %s: %w", c.relPath, err)
+		}
+		if info.Size() > maxGistFileBytes {
+			return fmt.Errorf("%s is %d bytes, exceeding GitHub's ~1 MiB per-file gist limit", c.relPath, info.Size())
+		}
+		total += info.Size()
 	}
-	defer os.RemoveAll(tempParent)
-
-	cloneDir := filepath.Join(tempParent, "clone")
-	cmd := exec.Command("gh", "gist", "clone", gistID, cloneDir)
-	var output bytes.Buffer
-	cmd.Stdout = &output
-	cmd.Stderr = &output
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf(
-			"failed to clone gist metadata (retry manually: 'gh gist clone %s <tempdir>' then move .git into %s): %v\n%s",
-			gistID,
-			targetDir,
-			err,
-			strings.TrimSpace(output.String()),
-		)
-	}
-	trimmed := strings.TrimSpace(output.String())
-	if trimmed != "" {
-		log.Verbose("gh gist clone output:\n%s", trimmed)
-	}
-	if err := moveGitMetadata(cloneDir, targetDir); err != nil {
-		return fmt.Errorf(
-			"failed to move git metadata (run 'gh gist clone %s <tempdir>' and move .git into %s manually): %w",
-			gistID,
-			targetDir,
-			err,
-		)
+	if total > maxGistTotalBytes {
+		return fmt.Errorf("gathered files total %d bytes, exceeding GitHub's ~10 MiB gist size limit", total)
 	}
 	return nil
 }
 
-func moveGitMetadata(from, to string) error {
-	entries, err := os.ReadDir(from)
-	if err != nil {
-		return fmt.Errorf("inspect cloned gist: %w", err)
+// encodeGistCreateRequest writes a gist create request body to w, reading
+// each candidate's content from disk immediately before encoding it and
+// discarding it immediately after, so only one file's bytes are held in
+// memory at a time.
+func encodeGistCreateRequest(w io.Writer, candidates []fileCandidate, opts options) error {
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+
+	if _, err := buf.WriteString(`{"public":`); err != nil {
+		return err
 	}
-	movedGitDir := false
-	for _, entry := range entries {
-		name := entry.Name()
-		if !strings.HasPrefix(name, ".git") {
-			continue
+	if err := enc.Encode(opts.public); err != nil {
+		return err
+	}
+	if opts.description != "" {
+		if _, err := buf.WriteString(`,"description":`); err != nil {
+			return err
 		}
-		if name == ".gitignore" {
-			continue
+		if err := enc.Encode(opts.description); err != nil {
+			return err
+		}
+	}
+	if _, err := buf.WriteString(`,"files":{`); err != nil {
+		return err
+	}
+	for i, c := range candidates {
+		if i > 0 {
+			if _, err := buf.WriteString(","); err != nil {
+				return err
+			}
 		}
-		src := filepath.Join(from, name)
-		dst := filepath.Join(to, name)
-		if err := os.RemoveAll(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
-			return fmt.Errorf("prepare destination %s: %w", dst, err)
+		if err := enc.Encode(c.name); err != nil {
+			return err
 		}
-		if err := moveFileOrDir(src, dst); err != nil {
-			return fmt.Errorf("move %s into target directory: %w", name, err)
+		content, err := os.ReadFile(c.path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", c.relPath, err)
+		}
+		if _, err := buf.WriteString(`:{"content":`); err != nil {
+			return err
+		}
+		if err := enc.Encode(string(content)); err != nil {
+			return err
 		}
-		if name == ".git" {
-			movedGitDir = true
+		content = nil
+		if _, err := buf.WriteString("}"); err != nil {
+			return err
 		}
 	}
-	if !movedGitDir {
-		return errors.New("cloned gist did not include a .git directory")
+	if _, err := buf.WriteString("}}"); err != nil {
+		return err
 	}
-	return nil
+	return buf.Flush()
 }
 
-// moveFileOrDir moves a file or directory from src to dst.
-// It first attempts os.Rename, and falls back to a copy-then-delete
-// approach if the rename fails due to a cross-device link error.
-func moveFileOrDir(src, dst string) error {
-	err := os.Rename(src, dst)
-	if err == nil {
-		return nil
+func runUpdate(args []string) error {
+	opts, err := parseUpdateArgs(args)
+	if err != nil {
+		return err
+	}
+	log := logger{verbose: opts.verbose}
+
+	log.Info("Resolving gist clone…")
+	targetDir, err := resolveExistingGistDirectory(opts.name)
+	if err != nil {
+		return err
 	}
-	// Check for cross-device link error (EXDEV)
-	var linkErr *os.LinkError
-	if !errors.As(err, &linkErr) {
+	log.Verbose("Target directory: %s", targetDir)
+
+	gistID, remoteHost, err := gistRemoteInfo(targetDir)
+	if err != nil {
 		return err
 	}
-	// Fall back to copy + delete for cross-device moves
-	if err := copyDir(src, dst); err != nil {
-		return fmt.Errorf("copy: %w", err)
+	log.Verbose("Detected gist ID %s from origin remote", gistID)
+
+	hostname := opts.hostname
+	if hostname == "" {
+		hostname = remoteHost
 	}
-	if err := os.RemoveAll(src); err != nil {
-		return fmt.Errorf("remove source after copy: %w", err)
+	client, err := newGistClient(hostname)
+	if err != nil {
+		return fmt.Errorf("init GitHub client: %w", err)
 	}
-	return nil
-}
 
-// copyDir recursively copies a directory tree from src to dst.
-func copyDir(src, dst string) error {
-	srcInfo, err := os.Stat(src)
+	existing, err := fetchGist(client, gistID)
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
+
+	log.Info("Collecting files for update…")
+	candidates, err := gatherFiles(targetDir, filepath.Base(targetDir), opts, log)
+	if err != nil {
 		return err
 	}
-	entries, err := os.ReadDir(src)
+	files, err := readCandidates(candidates, opts, log)
 	if err != nil {
 		return err
 	}
-	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
-		if entry.IsDir() {
-			if err := copyDir(srcPath, dstPath); err != nil {
-				return err
+
+	req := buildUpdateRequest(existing, files, opts, log)
+	if err := submitGistUpdate(client, gistID, req); err != nil {
+		return err
+	}
+
+	log.Info("Done! Gist updated: https://%s/%s", gistGitHost(hostname), gistID)
+	return nil
+}
+
+// fetchGist retrieves a gist's current state through client, the seam that
+// lets update's diffing logic be tested against a fake gistClient.
+func fetchGist(client gistClient, gistID string) (gistGetResponse, error) {
+	var existing gistGetResponse
+	if err := client.Get(fmt.Sprintf("gists/%s", gistID), &existing); err != nil {
+		return gistGetResponse{}, fmt.Errorf("fetch current gist %s: %w", gistID, err)
+	}
+	return existing, nil
+}
+
+// submitGistUpdate sends req through client, the seam that lets update's
+// request-building logic be tested against a fake gistClient.
+func submitGistUpdate(client gistClient, gistID string, req gistUpdateRequest) error {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("encode gist update payload: %w", err)
+	}
+	if err := client.Patch(fmt.Sprintf("gists/%s", gistID), bytes.NewReader(payload), nil); err != nil {
+		return fmt.Errorf("update gist %s via GitHub API: %w", gistID, err)
+	}
+	return nil
+}
+
+func parseUpdateArgs(args []string) (options, error) {
+	var opts options
+	fs := flag.NewFlagSet("gh gist-new update", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&opts.recursive, "recursive", false, "include files in subdirectories, flattening their paths into filenames")
+	fs.StringVar(&opts.pathSeparator, "path-separator", defaultPathSeparator, "separator used to flatten nested paths")
+	fs.IntVar(&opts.concurrency, "concurrency", runtime.NumCPU(), "number of files to read in parallel")
+	fs.StringVar(&opts.hostname, "hostname", os.Getenv("GH_HOST"), "GitHub Enterprise Server hostname to target")
+	fs.BoolVar(&opts.verbose, "verbose", false, "enable verbose logging")
+	var desc stringFlag
+	fs.Var(&desc, "description", "new description for the gist")
+	fs.Var(&desc, "d", "new description for the gist")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, usageText)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.Usage()
+			return opts, errHelpRequested
+		}
+		fs.Usage()
+		return opts, fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	remaining := fs.Args()
+	name := "."
+	if len(remaining) == 1 {
+		name = strings.TrimSpace(remaining[0])
+	} else if len(remaining) > 1 {
+		fs.Usage()
+		return opts, errors.New("only one [name] argument is supported")
+	}
+	if err := validateName(name); err != nil {
+		fs.Usage()
+		return opts, err
+	}
+	opts.name = name
+	if desc.set {
+		opts.description = strings.TrimSpace(desc.value)
+		if opts.description == "" {
+			return opts, errors.New("description cannot be empty when provided")
+		}
+	}
+	return opts, nil
+}
+
+// resolveExistingGistDirectory locates the directory previously created by
+// "gh gist-new" for [name] and confirms it holds a gist clone, so "update"
+// has something to sync against.
+func resolveExistingGistDirectory(name string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("determine working directory: %w", err)
+	}
+	target := cwd
+	if name != "." {
+		target = filepath.Join(cwd, name)
+	}
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return "", fmt.Errorf("resolve directory path: %w", err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("inspect directory %s: %w", abs, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", abs)
+	}
+	if _, err := os.Stat(filepath.Join(abs, ".git")); err != nil {
+		return "", fmt.Errorf("%s is not a gist clone (no .git found); run 'gh gist-new' first", abs)
+	}
+	return abs, nil
+}
+
+// decodeGistFilename reverses the --recursive flattening performed by
+// gatherFilesRecursive, turning a flattened gist filename back into the
+// relative path it was encoded from.
+func decodeGistFilename(name, separator string) string {
+	if separator == "" {
+		return name
+	}
+	return strings.ReplaceAll(name, separator, string(os.PathSeparator))
+}
+
+// gistRemoteInfo reads the origin remote that cloneGistMetadata created and
+// extracts the gist ID and, for gists cloned from a GitHub Enterprise Server
+// instance, the hostname to talk to the API on (empty for github.com, so
+// callers fall through to the default host).
+func gistRemoteInfo(dir string) (id string, host string, err error) {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("open git repository at %s: %w", dir, err)
+	}
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return "", "", fmt.Errorf("read origin remote: %w", err)
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", "", errors.New("origin remote has no URL")
+	}
+	u, err := url.Parse(urls[0])
+	if err != nil {
+		return "", "", fmt.Errorf("parse origin remote URL %q: %w", urls[0], err)
+	}
+	id = strings.TrimSuffix(path.Base(u.Path), ".git")
+	if id == "" || id == "." || id == "/" {
+		return "", "", fmt.Errorf("could not determine gist ID from remote URL %q", urls[0])
+	}
+	if u.Hostname() != "gist.github.com" {
+		host = u.Hostname()
+	}
+	return id, host, nil
+}
+
+// buildUpdateRequest diffs the freshly gathered local files against the
+// gist's current contents: local files are uploaded, files missing locally
+// are deleted (by sending a null body, as the gist API requires), and a
+// local file whose content exactly matches a file that's otherwise being
+// deleted is treated as a rename rather than a delete+add.
+func buildUpdateRequest(existing gistGetResponse, files []filePayload, opts options, log logger) gistUpdateRequest {
+	separator := opts.pathSeparator
+	if separator == "" {
+		separator = defaultPathSeparator
+	}
+
+	req := gistUpdateRequest{Files: make(map[string]*gistPatchFile, len(files))}
+	if opts.description != "" {
+		req.Description = opts.description
+	}
+
+	localByName := make(map[string]filePayload, len(files))
+	for _, f := range files {
+		localByName[f.Name] = f
+	}
+
+	renamedTo := make(map[string]bool)
+	for oldName, remoteFile := range existing.Files {
+		if _, stillPresent := localByName[oldName]; stillPresent {
+			continue
+		}
+		renamed := false
+		for _, f := range files {
+			if renamedTo[f.Name] {
+				continue
 			}
-		} else {
-			if err := copyFile(srcPath, dstPath); err != nil {
-				return err
+			if _, alreadyRemote := existing.Files[f.Name]; alreadyRemote {
+				continue
+			}
+			if string(f.Content) == remoteFile.Content {
+				newName := f.Name
+				req.Files[oldName] = &gistPatchFile{Filename: &newName, Content: string(f.Content)}
+				renamedTo[f.Name] = true
+				renamed = true
+				log.Verbose("Renaming %s to %s in gist", decodeGistFilename(oldName, separator), decodeGistFilename(newName, separator))
+				break
 			}
 		}
+		if !renamed {
+			req.Files[oldName] = nil
+			log.Verbose("Deleting %s from gist", decodeGistFilename(oldName, separator))
+		}
 	}
-	return nil
+
+	for _, f := range files {
+		if renamedTo[f.Name] {
+			continue
+		}
+		req.Files[f.Name] = &gistPatchFile{Content: string(f.Content)}
+	}
+
+	return req
 }
 
-// copyFile copies a single file from src to dst, preserving permissions.
-func copyFile(src, dst string) error {
-	srcFile, err := os.Open(src)
+// cloneGistMetadata clones the gist's git history directly into targetDir
+// using go-git, authenticating with the token `gh` already holds. Unlike
+// shelling out to `gh gist clone` (which refuses to clone into a non-empty
+// directory), go-git's PlainClone only requires that targetDir not already
+// contain a .git, so the freshly-written gist files can stay exactly where
+// gatherFiles left them.
+func cloneGistMetadata(gistID, hostname, targetDir string, log logger) (*git.Repository, error) {
+	token, err := ghAuthToken(hostname)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("obtain GitHub auth token: %w", err)
 	}
-	defer srcFile.Close()
 
-	srcInfo, err := srcFile.Stat()
+	cloneURL := fmt.Sprintf("https://%s/%s.git", gistGitHost(hostname), gistID)
+	log.Verbose("Cloning %s into %s", cloneURL, targetDir)
+	repo, err := git.PlainClone(targetDir, false, &git.CloneOptions{
+		URL:   cloneURL,
+		Depth: 1,
+		Auth: &githttp.BasicAuth{
+			Username: "x-access-token",
+			Password: token,
+		},
+	})
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("clone gist %s: %w", gistID, err)
+	}
+	return repo, nil
+}
+
+// gistGitHost returns the host that serves gist git clone/push traffic for
+// hostname: github.com's gists live under the special gist.github.com
+// domain, while GitHub Enterprise Server serves them directly off the
+// instance's own hostname.
+func gistGitHost(hostname string) string {
+	switch hostname {
+	case "", "github.com", "api.github.com":
+		return "gist.github.com"
+	default:
+		return hostname
 	}
+}
 
-	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, srcInfo.Mode())
+// ghAuthToken returns the token `gh` is currently authenticated with for
+// hostname (or the default host, if hostname is empty), for use as the
+// password half of a git HTTPS basic auth credential.
+func ghAuthToken(hostname string) (string, error) {
+	args := []string{"auth", "token"}
+	if hostname != "" {
+		args = append(args, "--hostname", hostname)
+	}
+	cmd := exec.Command("gh", args...)
+	output, err := cmd.Output()
 	if err != nil {
-		return err
+		return "", fmt.Errorf("run 'gh auth token': %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// pushInitialCommit stages the files gathered for the gist, commits them
+// using the gist description as the commit message, and pushes to the
+// origin remote that cloneGistMetadata created, so the target directory
+// ends up as a real working copy rather than files sitting next to an
+// otherwise-empty repository.
+func pushInitialCommit(repo *git.Repository, targetDir string, candidates []fileCandidate, opts options, log logger) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("open gist worktree: %w", err)
+	}
+	for _, c := range candidates {
+		if _, err := worktree.Add(c.name); err != nil {
+			return fmt.Errorf("stage %s: %w", c.name, err)
+		}
+	}
+
+	// The clone already contains whatever the gist API committed at
+	// creation, which is byte-identical to the files we just staged, so
+	// Add is typically a no-op against HEAD. Whole-worktree cleanliness
+	// isn't the right check here: files gatherFiles legitimately excluded
+	// (skipped dotfiles, or --recursive's original nested sources next to
+	// their flattened copies) are untracked and would make the worktree
+	// dirty even though nothing we staged actually changed. Look at the
+	// staged files themselves instead.
+	status, err := worktree.Status()
+	if err != nil {
+		return fmt.Errorf("check worktree status: %w", err)
+	}
+	staged := false
+	for _, c := range candidates {
+		if fileStatus, ok := status[c.name]; ok && fileStatus.Staging != git.Unmodified {
+			staged = true
+			break
+		}
+	}
+	if !staged {
+		log.Verbose("Clone already matches the gathered files; nothing to commit or push")
+		return nil
+	}
+
+	message := opts.description
+	if message == "" {
+		message = "Initial commit"
+	}
+	sig := gitSignature(targetDir)
+	log.Verbose("Committing %d file(s) as %s <%s>", len(candidates), sig.Name, sig.Email)
+	if _, err := worktree.Commit(message, &git.CommitOptions{Author: &sig}); err != nil {
+		return fmt.Errorf("commit gathered files: %w", err)
+	}
+
+	token, err := ghAuthToken(opts.hostname)
+	if err != nil {
+		return fmt.Errorf("obtain GitHub auth token: %w", err)
+	}
+	err = repo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		Auth: &githttp.BasicAuth{
+			Username: "x-access-token",
+			Password: token,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("push to origin: %w", err)
+	}
+	return nil
+}
+
+// gitSignature builds the commit author identity from dir's git
+// configuration (local scope, as set by ensureGitIdentity), falling back to
+// a generic identity when user.name or user.email is still not configured.
+func gitSignature(dir string) object.Signature {
+	name := gitConfigValue(dir, "user.name")
+	if name == "" {
+		name = "gh-gist-new"
+	}
+	email := gitConfigValue(dir, "user.email")
+	if email == "" {
+		email = "gh-gist-new@localhost"
+	}
+	return object.Signature{Name: name, Email: email, When: time.Now()}
+}
+
+func gitConfigValue(dir, key string) string {
+	output, err := exec.Command("git", "-C", dir, "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// ensureGitIdentity makes sure dir (the directory cloneGistMetadata just
+// cloned into) has a usable git identity, so the subsequent (or user-run)
+// `git commit` doesn't fail on a fresh machine where global user.name /
+// user.email were never set. It prefers --git-name/--git-email, then the
+// caller's global git config, and finally falls back to the authenticated
+// GitHub user's login and noreply email — setting whatever it picks with
+// --local scope so the user's global config is never touched.
+func ensureGitIdentity(dir string, opts options, log logger) error {
+	name := opts.gitName
+	if name == "" {
+		name = gitConfigValue(dir, "user.name")
+	}
+	email := opts.gitEmail
+	if email == "" {
+		email = gitConfigValue(dir, "user.email")
+	}
+
+	if name == "" || email == "" {
+		login, err := githubLogin(opts.hostname)
+		if err != nil {
+			return fmt.Errorf("determine GitHub user for git identity fallback: %w", err)
+		}
+		if name == "" {
+			name = login
+			log.Verbose("No git user.name configured; using GitHub login %q", login)
+		}
+		if email == "" {
+			email = fmt.Sprintf("%s@users.noreply.github.com", login)
+			log.Verbose("No git user.email configured; using GitHub noreply address %q", email)
+		}
 	}
-	defer dstFile.Close()
 
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
+	if err := setLocalGitConfig(dir, "user.name", name); err != nil {
 		return err
 	}
-	return dstFile.Close()
+	if err := setLocalGitConfig(dir, "user.email", email); err != nil {
+		return err
+	}
+	log.Verbose("Set local git identity in %s to %s <%s>", dir, name, email)
+	return nil
+}
+
+func githubLogin(hostname string) (string, error) {
+	client, err := newGistClient(hostname)
+	if err != nil {
+		return "", fmt.Errorf("init GitHub client: %w", err)
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := client.Get("user", &user); err != nil {
+		return "", fmt.Errorf("fetch authenticated GitHub user: %w", err)
+	}
+	if user.Login == "" {
+		return "", errors.New("GitHub API returned an empty user login")
+	}
+	return user.Login, nil
+}
+
+func setLocalGitConfig(dir, key, value string) error {
+	cmd := exec.Command("git", "-C", dir, "config", "--local", key, value)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("set local git config %s in %s: %v\n%s", key, dir, err, strings.TrimSpace(output.String()))
+	}
+	return nil
 }
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// gatherFilesFlat is what update's primary (non-recursive) path calls, and
+// resolveExistingGistDirectory guarantees a .git directory is present in the
+// directory being gathered. Make sure that doesn't trip the "subdirectory
+// detected" hard error that flat mode uses for genuine nested directories.
+func TestGatherFilesFlatSkipsGitDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0o755); err != nil {
+		t.Fatalf("create .git dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0o644); err != nil {
+		t.Fatalf("seed .git/HEAD: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.md"), []byte("hello"), 0o644); err != nil {
+		t.Fatalf("seed notes.md: %v", err)
+	}
+
+	files, err := gatherFilesFlat(dir, filepath.Base(dir), options{}, logger{})
+	if err != nil {
+		t.Fatalf("gatherFilesFlat returned unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, f := range files {
+		names = append(names, f.name)
+	}
+	sort.Strings(names)
+	if want := []string{"notes.md"}; len(names) != 1 || names[0] != want[0] {
+		t.Fatalf("gatherFilesFlat files = %v, want %v", names, want)
+	}
+}
+
+// A genuine nested subdirectory (not a dotfile) still requires --recursive.
+func TestGatherFilesFlatRejectsNonDotSubdirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0o755); err != nil {
+		t.Fatalf("create nested dir: %v", err)
+	}
+
+	_, err := gatherFilesFlat(dir, filepath.Base(dir), options{}, logger{})
+	if err == nil {
+		t.Fatal("gatherFilesFlat: expected error for non-dot subdirectory, got nil")
+	}
+}
+
+// fakeGistClient is an in-memory gistClient used to exercise fetchGist and
+// submitGistUpdate without making a real GitHub API call.
+type fakeGistClient struct {
+	getResponses map[string]gistGetResponse
+	patchedBody  []byte
+	patchPath    string
+}
+
+func (f *fakeGistClient) Post(path string, body io.Reader, response interface{}) error {
+	return fmt.Errorf("Post not supported by fakeGistClient")
+}
+
+func (f *fakeGistClient) Get(path string, response interface{}) error {
+	resp, ok := f.getResponses[path]
+	if !ok {
+		return fmt.Errorf("fakeGistClient: no response stubbed for %s", path)
+	}
+	out, ok := response.(*gistGetResponse)
+	if !ok {
+		return fmt.Errorf("fakeGistClient: unexpected response type %T", response)
+	}
+	*out = resp
+	return nil
+}
+
+func (f *fakeGistClient) Patch(path string, body io.Reader, response interface{}) error {
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	f.patchPath = path
+	f.patchedBody = raw
+	return nil
+}
+
+func TestFetchGistUsesClientSeam(t *testing.T) {
+	client := &fakeGistClient{
+		getResponses: map[string]gistGetResponse{
+			"gists/abc123": {
+				ID:    "abc123",
+				Files: map[string]gistFile{"notes.md": {Content: "hello"}},
+			},
+		},
+	}
+
+	got, err := fetchGist(client, "abc123")
+	if err != nil {
+		t.Fatalf("fetchGist returned unexpected error: %v", err)
+	}
+	if got.ID != "abc123" || got.Files["notes.md"].Content != "hello" {
+		t.Fatalf("fetchGist = %+v, want gist abc123 with notes.md = hello", got)
+	}
+}
+
+func TestSubmitGistUpdateSendsBuiltRequest(t *testing.T) {
+	client := &fakeGistClient{}
+	req := gistUpdateRequest{Files: map[string]*gistPatchFile{
+		"notes.md": {Content: "updated"},
+	}}
+
+	if err := submitGistUpdate(client, "abc123", req); err != nil {
+		t.Fatalf("submitGistUpdate returned unexpected error: %v", err)
+	}
+	if client.patchPath != "gists/abc123" {
+		t.Fatalf("patchPath = %q, want %q", client.patchPath, "gists/abc123")
+	}
+	var sent gistUpdateRequest
+	if err := json.Unmarshal(client.patchedBody, &sent); err != nil {
+		t.Fatalf("unmarshal patched body: %v", err)
+	}
+	if sent.Files["notes.md"].Content != "updated" {
+		t.Fatalf("patched content = %q, want %q", sent.Files["notes.md"].Content, "updated")
+	}
+}
+
+// TestBuildUpdateRequestDiffing is the table test the gistClient seam exists
+// to make possible: it drives buildUpdateRequest's delete/rename/add logic
+// against a fake remote gist fetched through a fakeGistClient.
+func TestBuildUpdateRequestDiffing(t *testing.T) {
+	tests := []struct {
+		name       string
+		remote     map[string]gistFile
+		local      []filePayload
+		wantDelete []string
+		wantRename map[string]string // oldName -> newName
+		wantAdd    map[string]string // name -> content
+	}{
+		{
+			name:       "unchanged file is re-sent with the same content",
+			remote:     map[string]gistFile{"notes.md": {Content: "hello"}},
+			local:      []filePayload{{Name: "notes.md", Content: []byte("hello")}},
+			wantDelete: nil,
+			wantRename: map[string]string{},
+			wantAdd:    map[string]string{"notes.md": "hello"},
+		},
+		{
+			name:       "removed local file is deleted remotely",
+			remote:     map[string]gistFile{"old.md": {Content: "stale"}},
+			local:      nil,
+			wantDelete: []string{"old.md"},
+			wantRename: map[string]string{},
+			wantAdd:    map[string]string{},
+		},
+		{
+			name:       "renamed file with unchanged content is a rename",
+			remote:     map[string]gistFile{"old.md": {Content: "same content"}},
+			local:      []filePayload{{Name: "new.md", Content: []byte("same content")}},
+			wantDelete: nil,
+			wantRename: map[string]string{"old.md": "new.md"},
+			wantAdd:    map[string]string{},
+		},
+		{
+			name:       "changed content under the same name is uploaded, not deleted or renamed",
+			remote:     map[string]gistFile{"file.md": {Content: "old content"}},
+			local:      []filePayload{{Name: "file.md", Content: []byte("new content")}},
+			wantDelete: nil,
+			wantRename: map[string]string{},
+			wantAdd:    map[string]string{"file.md": "new content"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := gistGetResponse{ID: "abc123", Files: tt.remote}
+			client := &fakeGistClient{getResponses: map[string]gistGetResponse{"gists/abc123": existing}}
+
+			fetched, err := fetchGist(client, "abc123")
+			if err != nil {
+				t.Fatalf("fetchGist returned unexpected error: %v", err)
+			}
+
+			req := buildUpdateRequest(fetched, tt.local, options{}, logger{})
+
+			gotDelete := []string{}
+			gotRename := map[string]string{}
+			gotAdd := map[string]string{}
+			for name, patch := range req.Files {
+				switch {
+				case patch == nil:
+					gotDelete = append(gotDelete, name)
+				case patch.Filename != nil:
+					gotRename[name] = *patch.Filename
+				default:
+					gotAdd[name] = patch.Content
+				}
+			}
+			sort.Strings(gotDelete)
+
+			if len(gotDelete) == 0 {
+				gotDelete = nil
+			}
+			if !equalStringSlices(gotDelete, tt.wantDelete) {
+				t.Errorf("deletes = %v, want %v", gotDelete, tt.wantDelete)
+			}
+			if !equalStringMaps(gotRename, tt.wantRename) {
+				t.Errorf("renames = %v, want %v", gotRename, tt.wantRename)
+			}
+			if !equalStringMaps(gotAdd, tt.wantAdd) {
+				t.Errorf("adds = %v, want %v", gotAdd, tt.wantAdd)
+			}
+		})
+	}
+}
+
+func equalStringSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalStringMaps(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGistGitHost(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     string
+	}{
+		{hostname: "", want: "gist.github.com"},
+		{hostname: "github.com", want: "gist.github.com"},
+		{hostname: "api.github.com", want: "gist.github.com"},
+		{hostname: "github.example.com", want: "github.example.com"},
+	}
+	for _, tt := range tests {
+		if got := gistGitHost(tt.hostname); got != tt.want {
+			t.Errorf("gistGitHost(%q) = %q, want %q", tt.hostname, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeGistCreateRequestStreamsFilesFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	aPath := filepath.Join(dir, "a.md")
+	bPath := filepath.Join(dir, "b.md")
+	if err := os.WriteFile(aPath, []byte("alpha"), 0o644); err != nil {
+		t.Fatalf("seed a.md: %v", err)
+	}
+	if err := os.WriteFile(bPath, []byte("beta"), 0o644); err != nil {
+		t.Fatalf("seed b.md: %v", err)
+	}
+
+	candidates := []fileCandidate{
+		{name: "a.md", relPath: "a.md", path: aPath},
+		{name: "b.md", relPath: "b.md", path: bPath},
+	}
+	opts := options{public: true, description: "test gist"}
+
+	var buf bytes.Buffer
+	if err := encodeGistCreateRequest(&buf, candidates, opts); err != nil {
+		t.Fatalf("encodeGistCreateRequest returned unexpected error: %v", err)
+	}
+
+	var got struct {
+		Public      bool                `json:"public"`
+		Description string              `json:"description"`
+		Files       map[string]gistFile `json:"files"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("decode encoded request: %v (body: %s)", err, buf.String())
+	}
+	if !got.Public || got.Description != "test gist" {
+		t.Fatalf("got public=%v description=%q, want public=true description=%q", got.Public, got.Description, "test gist")
+	}
+	if got.Files["a.md"].Content != "alpha" || got.Files["b.md"].Content != "beta" {
+		t.Fatalf("got files = %+v, want a.md=alpha b.md=beta", got.Files)
+	}
+}
+
+func TestValidateCandidateSizesRejectsOversizedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "big.md")
+	if err := os.WriteFile(path, make([]byte, maxGistFileBytes+1), 0o644); err != nil {
+		t.Fatalf("seed big.md: %v", err)
+	}
+
+	err := validateCandidateSizes([]fileCandidate{{name: "big.md", relPath: "big.md", path: path}})
+	if err == nil {
+		t.Fatal("validateCandidateSizes: expected error for oversized file, got nil")
+	}
+}